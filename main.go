@@ -1,40 +1,202 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"image"
 	"image/png"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/pdf417"
 	"github.com/boombuler/barcode/qr"
 	"github.com/go-pdf/fpdf"
+	fpdfbarcode "github.com/go-pdf/fpdf/contrib/barcode"
+	"gopkg.in/yaml.v3"
 )
 
+// qrCodeMarginX is the horizontal gap left between a label's code and its
+// text, regardless of which sheet or symbology is in use.
+const qrCodeMarginX = 0.5
+
+// pdf417SecurityLevel is the error-correction level (0-8) passed to
+// pdf417.Encode; 5 is a reasonable default for small printed labels.
+const pdf417SecurityLevel byte = 5
+
+// Supported --symbology values.
 const (
-	// Label sheet constants (Avery L4731REV-25)
-	labelsPerPage = 189
-	labelsAcross  = 7
-	labelsDown    = 27
-
-	// Label dimensions in millimeters
-	labelWidth    = 25.4
-	labelHeight   = 10.0
-	labelGutterX  = 2.55
-	marginLeft    = 8.45
-	marginTop     = 13.5
-	qrCodeSize    = 9.0
-	qrCodeMarginX = 0.5
-	qrCodeOffsetY = 0.5
+	symbologyQR         = "qr"
+	symbologyDataMatrix = "datamatrix"
+	symbologyCode128    = "code128"
+	symbologyPDF417     = "pdf417"
+	symbologyEAN13      = "ean13"
+	symbologyI2of5      = "i2of5"
 )
 
+// LabelSheet describes the physical layout of one label sheet template, in
+// millimeters. An empty PageSize means a continuous tape printer (e.g. a
+// Brother DK roll), where every "page" is sized to exactly one label.
+type LabelSheet struct {
+	Name         string  `json:"name" yaml:"name"`
+	PageSize     string  `json:"pageSize" yaml:"pageSize"`
+	LabelsAcross int     `json:"labelsAcross" yaml:"labelsAcross"`
+	LabelsDown   int     `json:"labelsDown" yaml:"labelsDown"`
+	LabelW       float64 `json:"labelW" yaml:"labelW"`
+	LabelH       float64 `json:"labelH" yaml:"labelH"`
+	GutterX      float64 `json:"gutterX" yaml:"gutterX"`
+	GutterY      float64 `json:"gutterY" yaml:"gutterY"`
+	MarginLeft   float64 `json:"marginLeft" yaml:"marginLeft"`
+	MarginTop    float64 `json:"marginTop" yaml:"marginTop"`
+	CodeSize     float64 `json:"codeSize" yaml:"codeSize"`
+	CodeOffsetY  float64 `json:"codeOffsetY" yaml:"codeOffsetY"`
+	Font         string  `json:"font" yaml:"font"`
+	FontSize     float64 `json:"fontSize" yaml:"fontSize"`
+}
+
+// LabelsPerPage returns how many labels fit on one page of this sheet.
+func (s LabelSheet) LabelsPerPage() int {
+	return s.LabelsAcross * s.LabelsDown
+}
+
+// sheetRegistry holds the built-in sheet templates, keyed by the name used
+// with --sheet / sheet=.
+var sheetRegistry = map[string]LabelSheet{
+	"avery-l4731": {
+		Name: "Avery L4731REV-25", PageSize: "A4",
+		LabelsAcross: 7, LabelsDown: 27,
+		LabelW: 25.4, LabelH: 10.0,
+		GutterX: 2.55, GutterY: 0,
+		MarginLeft: 8.45, MarginTop: 13.5,
+		CodeSize: 9.0, CodeOffsetY: 0.5,
+		Font: "Helvetica", FontSize: 8,
+	},
+	"avery-l7160": {
+		Name: "Avery L7160", PageSize: "A4",
+		LabelsAcross: 3, LabelsDown: 7,
+		LabelW: 63.5, LabelH: 38.1,
+		GutterX: 2.5, GutterY: 0,
+		MarginLeft: 7.2, MarginTop: 15.1,
+		CodeSize: 9.0, CodeOffsetY: 0.5,
+		Font: "Helvetica", FontSize: 8,
+	},
+	"avery-5160": {
+		Name: "Avery 5160", PageSize: "Letter",
+		LabelsAcross: 3, LabelsDown: 10,
+		LabelW: 66.675, LabelH: 25.4,
+		GutterX: 3.175, GutterY: 0,
+		MarginLeft: 4.7625, MarginTop: 12.7,
+		CodeSize: 9.0, CodeOffsetY: 0.5,
+		Font: "Helvetica", FontSize: 8,
+	},
+	"herma-4333": {
+		Name: "Herma 4333", PageSize: "A4",
+		LabelsAcross: 5, LabelsDown: 13,
+		LabelW: 39.0, LabelH: 21.0,
+		GutterX: 2.5, GutterY: 0,
+		MarginLeft: 7.25, MarginTop: 13.5,
+		CodeSize: 9.0, CodeOffsetY: 0.5,
+		Font: "Helvetica", FontSize: 8,
+	},
+	"brother-dk22205": {
+		Name: "Brother DK-22205 (continuous tape)", PageSize: "",
+		LabelsAcross: 1, LabelsDown: 1,
+		LabelW: 62.0, LabelH: 29.0,
+		GutterX: 0, GutterY: 0,
+		MarginLeft: 2.0, MarginTop: 2.0,
+		CodeSize: 9.0, CodeOffsetY: 0.5,
+		Font: "Helvetica", FontSize: 8,
+	},
+}
+
+// registeredSheetNames returns the built-in sheet names, sorted.
+func registeredSheetNames() []string {
+	names := make([]string, 0, len(sheetRegistry))
+	for name := range sheetRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveSheet looks up a sheet by name, or loads one from file if file is
+// set. file takes precedence over name.
+func resolveSheet(name, file string) (LabelSheet, error) {
+	if file != "" {
+		return loadSheetFile(file)
+	}
+	sheet, ok := sheetRegistry[name]
+	if !ok {
+		return LabelSheet{}, fmt.Errorf("unknown sheet %q (run with -sheet-file or pick one of: %s)", name, strings.Join(registeredSheetNames(), ", "))
+	}
+	return sheet, nil
+}
+
+// loadSheetFile reads a custom LabelSheet template from a YAML or JSON file.
+func loadSheetFile(path string) (LabelSheet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return LabelSheet{}, fmt.Errorf("failed to read sheet file: %v", err)
+	}
+
+	var sheet LabelSheet
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, &sheet)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &sheet)
+	default:
+		return LabelSheet{}, fmt.Errorf("unsupported sheet file extension %q (want .json, .yaml or .yml)", ext)
+	}
+	if err != nil {
+		return LabelSheet{}, fmt.Errorf("failed to parse sheet file %s: %v", path, err)
+	}
+	return sheet, nil
+}
+
+// parseStartPosition parses a "R,C" --start-position value into its 1-based
+// row and column.
+func parseStartPosition(s string) (row, col int, err error) {
+	parts := strings.SplitN(s, ",", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid start-position %q (want R,C)", s)
+	}
+	row, err = strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start-position row %q: %v", parts[0], err)
+	}
+	col, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start-position col %q: %v", parts[1], err)
+	}
+	if row < 1 || col < 1 {
+		return 0, 0, fmt.Errorf("start-position row/col must be >= 1, got %q", s)
+	}
+	return row, col, nil
+}
+
+// validateStartPosition rejects a start-position that falls outside sheet's
+// grid, which would otherwise make buildPDF skip more labels than fit on a
+// page and place subsequent pages' numbering out of order.
+func validateStartPosition(row, col int, sheet LabelSheet) error {
+	if row > sheet.LabelsDown || col > sheet.LabelsAcross {
+		return fmt.Errorf("start-position %d,%d is outside the %dx%d grid of sheet %q", row, col, sheet.LabelsDown, sheet.LabelsAcross, sheet.Name)
+	}
+	return nil
+}
+
 type config struct {
 	startNumber  int
 	prefix       string
@@ -42,46 +204,352 @@ type config struct {
 	outputFile   string
 	showBorders  bool
 	leadingZeros int
+	symbology    string
+	sheet        LabelSheet
+	startRow     int
+	startCol     int
+	inputLines   []string
+	bulkMode     bool
+	quiet        bool
+}
+
+// progressTracker tracks labels-done/total/current-page for a running job, so
+// CLI and HTTP progress reporting can read a consistent snapshot. All fields
+// are updated from the rendering goroutine and read concurrently, hence the
+// atomics. A nil *progressTracker is valid and simply ignores updates.
+type progressTracker struct {
+	done      int64
+	total     int
+	page      int64
+	startedAt time.Time
 }
 
-// tempFiles keeps track of temporary files we need to clean up
-type tempFiles struct {
-	files []string
-	mu    sync.Mutex
+func newProgressTracker(total int) *progressTracker {
+	return &progressTracker{total: total, startedAt: time.Now()}
 }
 
-func (tf *tempFiles) add(filename string) {
-	tf.mu.Lock()
-	tf.files = append(tf.files, filename)
-	tf.mu.Unlock()
+func (pt *progressTracker) addDone(n int) {
+	if pt == nil {
+		return
+	}
+	atomic.AddInt64(&pt.done, int64(n))
+}
+
+func (pt *progressTracker) setPage(page int) {
+	if pt == nil {
+		return
+	}
+	atomic.StoreInt64(&pt.page, int64(page))
+}
+
+// snapshot returns the current progress and an estimated time remaining,
+// extrapolated from the average time per label so far.
+func (pt *progressTracker) snapshot() (done, total, page int, eta time.Duration) {
+	done = int(atomic.LoadInt64(&pt.done))
+	total = pt.total
+	page = int(atomic.LoadInt64(&pt.page))
+	if done > 0 && done < total {
+		perLabel := time.Since(pt.startedAt) / time.Duration(done)
+		eta = perLabel * time.Duration(total-done)
+	}
+	return done, total, page, eta
+}
+
+// expectedLabelCount estimates how many labels a job will place, for sizing
+// its progress tracker.
+func expectedLabelCount(cfg config) int {
+	if cfg.bulkMode {
+		return len(cfg.inputLines)
+	}
+	skip := (cfg.startRow-1)*cfg.sheet.LabelsAcross + (cfg.startCol - 1)
+	total := cfg.pages*cfg.sheet.LabelsPerPage() - skip
+	if total < 0 {
+		total = 0
+	}
+	return total
+}
+
+// isTerminal reports whether f is attached to an interactive terminal, so the
+// CLI can choose between a live progress bar and periodic log lines.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// ceilDiv returns a divided by b, rounded up.
+func ceilDiv(a, b int) int {
+	if b <= 0 {
+		return 0
+	}
+	return (a + b - 1) / b
+}
+
+// pagesForBulk returns how many pages are needed to place lineCount bulk
+// labels, given that the first page only has room for perPage-skip of them
+// (the rest of its grid positions being left blank per --start-position).
+func pagesForBulk(lineCount, skip, perPage int) int {
+	firstPageCapacity := perPage - skip
+	if lineCount <= firstPageCapacity {
+		return 1
+	}
+	return 1 + ceilDiv(lineCount-firstPageCapacity, perPage)
+}
+
+// scanLines reads non-empty, trimmed lines from r.
+func scanLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read input: %v", err)
+	}
+	return lines, nil
+}
+
+// readInputLines reads bulk label payloads from a file, or from stdin when
+// path is "-".
+func readInputLines(path string) ([]string, error) {
+	var r io.Reader
+	if path == "-" {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open input file: %v", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	lines, err := scanLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("input %q contains no non-empty lines", path)
+	}
+	return lines, nil
 }
 
-func (tf *tempFiles) cleanup() {
-	tf.mu.Lock()
-	defer tf.mu.Unlock()
+// readBulkRequestBody extracts bulk label payloads from a POST /generate
+// body, supporting a raw text/plain body or a multipart/form-data upload
+// (field name "input").
+func readBulkRequestBody(r *http.Request) ([]string, error) {
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		file, _, err := r.FormFile("input")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read multipart \"input\" file: %v", err)
+		}
+		defer file.Close()
+		lines, err := scanLines(file)
+		if err != nil {
+			return nil, err
+		}
+		if len(lines) == 0 {
+			return nil, fmt.Errorf("uploaded input contains no non-empty lines")
+		}
+		return lines, nil
+	case strings.HasPrefix(contentType, "text/plain"):
+		lines, err := scanLines(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		if len(lines) == 0 {
+			return nil, fmt.Errorf("request body contains no non-empty lines")
+		}
+		return lines, nil
+	default:
+		return nil, fmt.Errorf("unsupported Content-Type %q for bulk input (want text/plain or multipart/form-data)", contentType)
+	}
+}
 
-	for _, f := range tf.files {
-		os.Remove(f)
+// textForLabel returns the payload to encode for the labelOrdinal-th label
+// (0-based, counting only labels actually placed) and whether one exists. In
+// bulk mode it's read from cfg.inputLines; otherwise it's the usual
+// prefix+zero-padded number.
+func textForLabel(cfg config, labelOrdinal, number int) (string, bool) {
+	if cfg.bulkMode {
+		if labelOrdinal >= len(cfg.inputLines) {
+			return "", false
+		}
+		return cfg.inputLines[labelOrdinal], true
 	}
-	tf.files = nil
+	return fmt.Sprintf("%s%0*d", cfg.prefix, cfg.leadingZeros, number), true
 }
 
 func main() {
+	// Allow `asn-qrcode-generator preview ...` as an alias for --preview, so
+	// users can proof a label before committing to a full sheet.
+	previewSubcommand := false
+	if len(os.Args) > 1 && os.Args[1] == "preview" {
+		previewSubcommand = true
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
 	serveFlag := flag.Bool("serve", false, "Run as HTTP server")
 	port := flag.String("port", "8080", "HTTP server port")
+	previewFlag := flag.Bool("preview", false, "Render a label's QR code as a terminal preview instead of producing a PDF")
+	previewLabel := flag.Int("preview-label", 1, "Which label (1-based, across all pages) to preview")
+	previewInvert := flag.Bool("preview-invert", false, "Invert preview colors for light-on-dark terminals")
 
 	cfg := parseFlags()
 
-	if *serveFlag {
+	switch {
+	case *serveFlag:
 		startServer(*port)
-	} else {
-		if err := generatePDF(cfg); err != nil {
+	case previewSubcommand || *previewFlag:
+		text, err := labelTextAt(cfg, *previewLabel)
+		if err != nil {
+			log.Fatalf("Error rendering preview: %v", err)
+		}
+		if err := printQRPreview(os.Stdout, text, *previewInvert); err != nil {
+			log.Fatalf("Error rendering preview: %v", err)
+		}
+	default:
+		pt := newProgressTracker(expectedLabelCount(cfg))
+		stop := make(chan struct{})
+		go runCLIProgress(pt, cfg.quiet, stop)
+
+		err := generatePDF(cfg, pt)
+		close(stop)
+		if err != nil {
 			log.Fatalf("Error generating PDF: %v", err)
 		}
 		fmt.Printf("Generated PDF file: %s\n", cfg.outputFile)
 	}
 }
 
+// labelTextAt returns the payload for the label-th label (1-based, across
+// all pages), for preview purposes.
+func labelTextAt(cfg config, label int) (string, error) {
+	if label < 1 {
+		return "", fmt.Errorf("preview-label must be >= 1, got %d", label)
+	}
+	ordinal := label - 1
+	number := cfg.startNumber + ordinal
+	text, ok := textForLabel(cfg, ordinal, number)
+	if !ok {
+		return "", fmt.Errorf("no label at position %d (input has %d lines)", label, len(cfg.inputLines))
+	}
+	return text, nil
+}
+
+// printQRPreview renders text's QR code to w as half-block Unicode: "█" when
+// both the top and bottom module of a character cell are dark, "▀"/"▄" when
+// only one is, and a space otherwise, with a 2-module quiet zone border. With
+// invert, foreground and background are swapped for light-on-dark terminals.
+func printQRPreview(w io.Writer, text string, invert bool) error {
+	code, err := qr.Encode(text, qr.M, qr.Auto)
+	if err != nil {
+		return fmt.Errorf("failed to encode QR code: %v", err)
+	}
+
+	const quietZone = 2
+	bounds := code.Bounds()
+
+	isDark := func(x, y int) bool {
+		dark := false
+		if x >= bounds.Min.X && x < bounds.Max.X && y >= bounds.Min.Y && y < bounds.Max.Y {
+			r, g, b, _ := code.At(x, y).RGBA()
+			dark = r == 0 && g == 0 && b == 0
+		}
+		if invert {
+			dark = !dark
+		}
+		return dark
+	}
+
+	var buf strings.Builder
+	minX, maxX := bounds.Min.X-quietZone, bounds.Max.X+quietZone
+	minY, maxY := bounds.Min.Y-quietZone, bounds.Max.Y+quietZone
+	for y := minY; y < maxY; y += 2 {
+		for x := minX; x < maxX; x++ {
+			top, bottom := isDark(x, y), isDark(x, y+1)
+			switch {
+			case top && bottom:
+				buf.WriteRune('█')
+			case top:
+				buf.WriteRune('▀')
+			case bottom:
+				buf.WriteRune('▄')
+			default:
+				buf.WriteRune(' ')
+			}
+		}
+		buf.WriteByte('\n')
+	}
+
+	_, err = io.WriteString(w, buf.String())
+	return err
+}
+
+// runCLIProgress reports pt's progress until stop is closed: a live,
+// overwriting bar when stdout is a TTY, or periodic log.Printf lines
+// otherwise. It does nothing when quiet is set.
+func runCLIProgress(pt *progressTracker, quiet bool, stop <-chan struct{}) {
+	if quiet {
+		return
+	}
+
+	tty := isTerminal(os.Stdout)
+	interval := 200 * time.Millisecond
+	if !tty {
+		interval = 2 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			if tty {
+				fmt.Println()
+			}
+			return
+		case <-ticker.C:
+			printCLIProgress(pt, tty)
+		}
+	}
+}
+
+func printCLIProgress(pt *progressTracker, tty bool) {
+	done, total, page, eta := pt.snapshot()
+	if tty {
+		fmt.Printf("\r[%s] %d/%d labels, page %d, ETA %s  ", progressBar(done, total, 30), done, total, page, formatETA(eta))
+	} else {
+		log.Printf("progress: %d/%d labels, page %d, ETA %s", done, total, page, formatETA(eta))
+	}
+}
+
+func progressBar(done, total, width int) string {
+	if total <= 0 {
+		return strings.Repeat(" ", width)
+	}
+	filled := done * width / total
+	if filled > width {
+		filled = width
+	}
+	return strings.Repeat("=", filled) + strings.Repeat(" ", width-filled)
+}
+
+func formatETA(eta time.Duration) string {
+	if eta <= 0 {
+		return "--"
+	}
+	return eta.Round(time.Second).String()
+}
+
 func parseFlags() config {
 	cfg := config{}
 
@@ -90,10 +558,45 @@ func parseFlags() config {
 	flag.IntVar(&cfg.pages, "pages", 1, "Number of pages to generate")
 	flag.StringVar(&cfg.outputFile, "output", "labels.pdf", "Output PDF file")
 	flag.BoolVar(&cfg.showBorders, "borders", false, "Show label borders (for debugging)")
+	flag.BoolVar(&cfg.quiet, "quiet", false, "Suppress progress output")
 	flag.IntVar(&cfg.leadingZeros, "zeros", 4, "Number of leading zeros in the number")
+	flag.StringVar(&cfg.symbology, "symbology", symbologyQR, "Barcode symbology: qr, datamatrix, code128, pdf417, ean13, i2of5")
+	sheetName := flag.String("sheet", "avery-l4731", "Label sheet template name")
+	sheetFile := flag.String("sheet-file", "", "Path to a custom sheet template (YAML or JSON), overrides -sheet")
+	startPosition := flag.String("start-position", "1,1", "Skip labels before row,col (1-based) on a partially used sheet")
+	inputFile := flag.String("input", "", "File with one label payload per line (bulk mode); use - for stdin. Overrides -start/-prefix/-zeros")
 
 	flag.Parse()
 
+	if err := validateSymbology(cfg.symbology); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	sheet, err := resolveSheet(*sheetName, *sheetFile)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	cfg.sheet = sheet
+
+	cfg.startRow, cfg.startCol, err = parseStartPosition(*startPosition)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if err := validateStartPosition(cfg.startRow, cfg.startCol, cfg.sheet); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	if *inputFile != "" {
+		lines, err := readInputLines(*inputFile)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		cfg.inputLines = lines
+		cfg.bulkMode = true
+		skip := (cfg.startRow-1)*cfg.sheet.LabelsAcross + (cfg.startCol - 1)
+		cfg.pages = pagesForBulk(len(lines), skip, cfg.sheet.LabelsPerPage())
+	}
+
 	// Ensure output directory exists
 	dir := filepath.Dir(cfg.outputFile)
 	if dir != "." {
@@ -105,13 +608,174 @@ func parseFlags() config {
 	return cfg
 }
 
+// validateSymbology rejects unknown --symbology values before any work starts.
+func validateSymbology(symbology string) error {
+	switch symbology {
+	case symbologyQR, symbologyDataMatrix, symbologyCode128, symbologyPDF417, symbologyEAN13, symbologyI2of5:
+		return nil
+	default:
+		return fmt.Errorf("unknown symbology %q (want qr, datamatrix, code128, pdf417, ean13 or i2of5)", symbology)
+	}
+}
+
+// isLinearSymbology reports whether symbology is a 1D barcode, which can be
+// auto-scaled to any width. QR, DataMatrix and PDF417 are 2D codes and must
+// stay square/aspect-correct to remain scannable.
+func isLinearSymbology(symbology string) bool {
+	switch symbology {
+	case symbologyCode128, symbologyEAN13, symbologyI2of5:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateSymbologyContent rejects label content that a symbology cannot
+// encode, e.g. EAN-13 requires exactly 12 or 13 numeric digits and
+// interleaved 2-of-5 requires an even count of numeric digits.
+func validateSymbologyContent(symbology, text string) error {
+	switch symbology {
+	case symbologyEAN13:
+		if len(text) != 12 && len(text) != 13 {
+			return fmt.Errorf("ean13 requires 12 or 13 numeric digits, got %q (%d chars)", text, len(text))
+		}
+		if !isNumeric(text) {
+			return fmt.Errorf("ean13 requires numeric content, got %q", text)
+		}
+	case symbologyI2of5:
+		if !isNumeric(text) {
+			return fmt.Errorf("i2of5 requires numeric content, got %q", text)
+		}
+		if len(text)%2 != 0 {
+			return fmt.Errorf("i2of5 (interleaved) requires an even number of digits, got %q (%d chars)", text, len(text))
+		}
+	}
+	return nil
+}
+
+// isNumeric reports whether text consists entirely of ASCII digits.
+func isNumeric(text string) bool {
+	if text == "" {
+		return false
+	}
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 func startServer(port string) {
 	http.HandleFunc("/generate", handleGenerate)
+	http.HandleFunc("/progress", handleProgress)
+	http.HandleFunc("/result", handleResult)
+	http.HandleFunc("/preview", handlePreview)
 	http.HandleFunc("/", handleRoot)
 	log.Printf("Starting server on port %s...", port)
 	log.Fatal(http.ListenAndServe(":"+port, nil))
 }
 
+// job tracks one in-flight or finished /generate request, identified by a
+// Job-Id the client polls via /progress and /result. All fields except
+// tracker (which has its own atomics) are guarded by mu.
+type job struct {
+	tracker *progressTracker
+
+	mu       sync.Mutex
+	done     bool
+	pdfBytes []byte
+	filename string
+	err      error
+}
+
+var (
+	jobsMu sync.Mutex
+	jobs   = map[string]*job{}
+	jobSeq int64
+)
+
+// newJobID returns a new, process-unique job identifier.
+func newJobID() string {
+	n := atomic.AddInt64(&jobSeq, 1)
+	return fmt.Sprintf("job-%d-%d", time.Now().UnixNano(), n)
+}
+
+// runGenerateJob renders cfg's PDF in the background and records the result
+// on j, where handleResult/handleProgress can pick it up.
+func runGenerateJob(cfg config, j *job) {
+	pdf, err := buildPDF(cfg, j.tracker)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err != nil {
+		j.err = err
+		j.done = true
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		j.err = fmt.Errorf("error encoding PDF: %v", err)
+		j.done = true
+		return
+	}
+	j.pdfBytes = buf.Bytes()
+	j.filename = cfg.outputFile
+	j.done = true
+}
+
+func handlePreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startNumber, _ := strconv.Atoi(r.URL.Query().Get("start"))
+	if startNumber == 0 {
+		startNumber = 1
+	}
+	leadingZeros, _ := strconv.Atoi(r.URL.Query().Get("zeros"))
+	if leadingZeros == 0 {
+		leadingZeros = 4
+	}
+	prefix := r.URL.Query().Get("prefix")
+	if prefix == "" {
+		prefix = "ASN"
+	}
+	invert, _ := strconv.ParseBool(r.URL.Query().Get("invert"))
+	label, _ := strconv.Atoi(r.URL.Query().Get("label"))
+	if label == 0 {
+		label = 1
+	}
+
+	cfg := config{
+		startNumber:  startNumber,
+		prefix:       prefix,
+		leadingZeros: leadingZeros,
+	}
+
+	// A text= override previews an arbitrary payload directly, bypassing the
+	// prefix/number scheme entirely.
+	if text := r.URL.Query().Get("text"); text != "" {
+		cfg.inputLines = []string{text}
+		cfg.bulkMode = true
+		label = 1
+	}
+
+	text, err := labelTextAt(cfg, label)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if err := printQRPreview(w, text, invert); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 func handleRoot(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/" {
 		http.NotFound(w, r)
@@ -119,6 +783,7 @@ func handleRoot(w http.ResponseWriter, r *http.Request) {
 	}
 
 	hostname, _ := os.Hostname()
+	sheetList := strings.Join(registeredSheetNames(), ", ")
 	info := fmt.Sprintf(`
     _    ____  _   _     ___  ____      ____          _      
    / \  / ___|| \ | |   / _ \|  _ \    / ___|___   __| | ___ 
@@ -137,11 +802,26 @@ API Usage:
 ----------
 Generate labels: GET /generate
 Parameters:
-  - start    : Starting ASN number (default: 1)
-  - prefix   : Prefix for ASN (default: "ASN")
-  - pages    : Number of pages (default: 1)
-  - zeros    : Number of leading zeros (default: 4)
-  - borders  : Show borders, true/false (default: false)
+  - start         : Starting ASN number (default: 1)
+  - prefix        : Prefix for ASN (default: "ASN")
+  - pages         : Number of pages (default: 1)
+  - zeros         : Number of leading zeros (default: 4)
+  - borders       : Show borders, true/false (default: false)
+  - symbology     : qr, datamatrix, code128, pdf417, ean13, i2of5 (default: "qr")
+  - sheet         : Label sheet template name (default: "avery-l4731")
+  - start-position: Skip labels before row,col (1-based), e.g. "2,1" (default: "1,1")
+
+Bulk mode: POST /generate
+  Send a text/plain body, or a multipart/form-data upload with field "input",
+  with one label payload per line. Overrides start/prefix/zeros; pages is
+  inferred from the line count.
+
+/generate renders asynchronously: it returns immediately (202 Accepted) with
+a Job-Id header/body, while rendering runs in a background worker. Poll
+progress and fetch the finished PDF with:
+
+  GET /progress?job=<id>  -> {"done","total","page","eta_seconds"}
+  GET /result?job=<id>    -> the PDF (202 while still running, 500 on error)
 
 Examples:
 --------
@@ -149,24 +829,34 @@ Basic usage:
   /generate?start=1000&prefix=ASN&pages=1
 
 With all parameters:
-  /generate?start=1000&prefix=ASN&pages=2&zeros=5&borders=true
+  /generate?start=1000&prefix=ASN&pages=2&zeros=5&borders=true&sheet=avery-l7160&start-position=2,1
+
+Bulk usage:
+  curl --data-binary @ids.txt -H "Content-Type: text/plain" "http://host/generate?sheet=avery-l7160"
 
-Label Sheet Info:
----------------
-Type: Avery L4731REV-25
-Layout: 7 x 27 (189 labels per page)
-Size: 25.4mm x 10.0mm
+Preview a label's QR before printing: GET /preview
+Parameters:
+  - start  : Starting ASN number (default: 1)
+  - prefix : Prefix for ASN (default: "ASN")
+  - zeros  : Number of leading zeros (default: 4)
+  - label  : Which label (1-based) to preview (default: 1)
+  - invert : Invert colors for light-on-dark terminals (default: false)
+  - text   : Preview this exact payload instead of the numeric scheme
+
+Registered Label Sheets:
+-----------------------
+%s
 
 For more information visit:
 https://github.com/tobiaswx/asn-qrcode-generator
-`, os.Args[0], hostname, time.Now().Format(time.RFC1123))
+`, os.Args[0], hostname, time.Now().Format(time.RFC1123), sheetList)
 
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 	fmt.Fprint(w, info)
 }
 
 func handleGenerate(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -193,6 +883,39 @@ func handleGenerate(w http.ResponseWriter, r *http.Request) {
 		prefix = "ASN"
 	}
 
+	symbology := r.URL.Query().Get("symbology")
+	if symbology == "" {
+		symbology = symbologyQR
+	}
+	if err := validateSymbology(symbology); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sheetName := r.URL.Query().Get("sheet")
+	if sheetName == "" {
+		sheetName = "avery-l4731"
+	}
+	sheet, err := resolveSheet(sheetName, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	startPosition := r.URL.Query().Get("start-position")
+	if startPosition == "" {
+		startPosition = "1,1"
+	}
+	startRow, startCol, err := parseStartPosition(startPosition)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateStartPosition(startRow, startCol, sheet); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Convert to config
 	cfg := config{
 		startNumber:  startNumber,
@@ -201,85 +924,277 @@ func handleGenerate(w http.ResponseWriter, r *http.Request) {
 		outputFile:   fmt.Sprintf("asn-%d.pdf", startNumber),
 		showBorders:  showBorders,
 		leadingZeros: leadingZeros,
+		symbology:    symbology,
+		sheet:        sheet,
+		startRow:     startRow,
+		startCol:     startCol,
 	}
 
-	// Generate PDF
-	if err := generatePDF(cfg); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	// A POST body supplies one label payload per line (bulk mode), overriding
+	// start/prefix/zeros; pages is replaced by the count inferred from the
+	// number of lines.
+	if r.Method == http.MethodPost {
+		lines, err := readBulkRequestBody(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cfg.inputLines = lines
+		cfg.bulkMode = true
+		skip := (startRow-1)*cfg.sheet.LabelsAcross + (startCol - 1)
+		cfg.pages = pagesForBulk(len(lines), skip, cfg.sheet.LabelsPerPage())
+	}
+
+	// Rendering happens in a background goroutine so the request returns
+	// immediately; the client polls /progress and fetches /result once done.
+	// This also sidesteps the asn-<N>.pdf filename collision between
+	// concurrent requests that the old synchronous path had.
+	j := &job{tracker: newProgressTracker(expectedLabelCount(cfg))}
+	jobID := newJobID()
+	jobsMu.Lock()
+	jobs[jobID] = j
+	jobsMu.Unlock()
+
+	go runGenerateJob(cfg, j)
+
+	w.Header().Set("Job-Id", jobID)
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintf(w, "Job accepted: %s\nPoll progress: GET /progress?job=%s\nFetch result:  GET /result?job=%s\n", jobID, jobID, jobID)
+}
+
+// handleProgress reports a running job's progress as JSON.
+func handleProgress(w http.ResponseWriter, r *http.Request) {
+	jobID := r.URL.Query().Get("job")
+	j, ok := lookupJob(jobID)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown job %q", jobID), http.StatusNotFound)
 		return
 	}
 
-	// Send file
-	w.Header().Set("Content-Type", "application/pdf")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", cfg.outputFile))
-	http.ServeFile(w, r, cfg.outputFile)
+	done, total, page, eta := j.tracker.snapshot()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"done":        done,
+		"total":       total,
+		"page":        page,
+		"eta_seconds": eta.Seconds(),
+	})
+}
+
+// handleResult returns a job's finished PDF, or a 202/500 status while it's
+// still running or if it failed. A terminal fetch (success or error) evicts
+// the job, so a long-lived server doesn't accumulate one PDF's worth of RAM
+// per /generate request forever.
+func handleResult(w http.ResponseWriter, r *http.Request) {
+	jobID := r.URL.Query().Get("job")
+	j, ok := lookupJob(jobID)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown job %q", jobID), http.StatusNotFound)
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	switch {
+	case !j.done:
+		http.Error(w, "job still running, try again later", http.StatusAccepted)
+	case j.err != nil:
+		deleteJob(jobID)
+		http.Error(w, j.err.Error(), http.StatusInternalServerError)
+	default:
+		deleteJob(jobID)
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", j.filename))
+		w.Write(j.pdfBytes)
+	}
+}
+
+// lookupJob retrieves a job by id.
+func lookupJob(jobID string) (*job, bool) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	j, ok := jobs[jobID]
+	return j, ok
+}
 
-	// Clean up the file after sending
-	defer os.Remove(cfg.outputFile)
+// deleteJob removes a job from the registry, e.g. once its result has been
+// fetched, so finished jobs don't leak memory in a long-running server.
+func deleteJob(jobID string) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	delete(jobs, jobID)
 }
 
-func generatePDF(cfg config) error {
-	tf := &tempFiles{
-		files: make([]string, 0, labelsPerPage),
+// generatePDF builds the label PDF for cfg and writes it to cfg.outputFile.
+// pt may be nil if progress reporting isn't needed.
+func generatePDF(cfg config, pt *progressTracker) error {
+	pdf, err := buildPDF(cfg, pt)
+	if err != nil {
+		return err
 	}
-	// Ensure cleanup happens after we're done
-	defer tf.cleanup()
 
-	// Create PDF
-	pdf := fpdf.New("P", "mm", "A4", "")
-	pdf.SetMargins(marginLeft, marginTop, marginLeft)
+	if err := pdf.OutputFileAndClose(cfg.outputFile); err != nil {
+		return fmt.Errorf("error saving PDF: %v", err)
+	}
+
+	return nil
+}
+
+// buildPDF renders the label PDF for cfg entirely in memory, ready to be
+// written to a file or streamed to an http.ResponseWriter. pt may be nil if
+// progress reporting isn't needed.
+func buildPDF(cfg config, pt *progressTracker) (*fpdf.Fpdf, error) {
+	// Create PDF. Continuous-tape sheets (PageSize == "") get a custom page
+	// size matching a single label instead of a fixed A4/Letter sheet.
+	var pdf *fpdf.Fpdf
+	if cfg.sheet.PageSize == "" {
+		pdf = fpdf.NewCustom(&fpdf.InitType{
+			OrientationStr: "P",
+			UnitStr:        "mm",
+			Size:           fpdf.SizeType{Wd: cfg.sheet.LabelW, Ht: cfg.sheet.LabelH},
+		})
+	} else {
+		pdf = fpdf.New("P", "mm", cfg.sheet.PageSize, "")
+	}
+	pdf.SetMargins(cfg.sheet.MarginLeft, cfg.sheet.MarginTop, cfg.sheet.MarginLeft)
+
+	skip := (cfg.startRow-1)*cfg.sheet.LabelsAcross + (cfg.startCol - 1)
 
 	// Generate labels for requested number of pages
+	placed := 0
 	for page := 0; page < cfg.pages; page++ {
 		pdf.AddPage()
+		pt.setPage(page + 1)
 
-		startNum := cfg.startNumber + (page * labelsPerPage)
-		if err := generatePage(pdf, startNum, cfg, tf); err != nil {
-			return fmt.Errorf("error generating page %d: %v", page+1, err)
+		pageSkip := 0
+		if page == 0 {
+			pageSkip = skip
 		}
-	}
 
-	if err := pdf.OutputFileAndClose(cfg.outputFile); err != nil {
-		return fmt.Errorf("error saving PDF: %v", err)
+		startNum := cfg.startNumber + placed
+		if err := generatePage(pdf, startNum, cfg, pageSkip, pt); err != nil {
+			return nil, fmt.Errorf("error generating page %d: %v", page+1, err)
+		}
+		placed += cfg.sheet.LabelsPerPage() - pageSkip
 	}
 
-	return nil
+	return pdf, nil
 }
 
-func generatePage(pdf *fpdf.Fpdf, startNum int, cfg config, tf *tempFiles) error {
-	for row := 0; row < labelsDown; row++ {
-		for col := 0; col < labelsAcross; col++ {
-			currentNum := startNum + (row * labelsAcross) + col
+// generatePage draws one page of labels starting at startNum. skip is the
+// number of leading grid positions (row-major) to leave blank, for printing
+// onto a sheet that already had some labels peeled off. pt is advanced once
+// per label actually drawn.
+func generatePage(pdf *fpdf.Fpdf, startNum int, cfg config, skip int, pt *progressTracker) error {
+	sheet := cfg.sheet
+	for row := 0; row < sheet.LabelsDown; row++ {
+		for col := 0; col < sheet.LabelsAcross; col++ {
+			posIndex := row*sheet.LabelsAcross + col
+			if posIndex < skip {
+				continue
+			}
+			labelOrdinal := (startNum - cfg.startNumber) + (posIndex - skip)
+			currentNum := startNum + (posIndex - skip)
+
+			text, ok := textForLabel(cfg, labelOrdinal, currentNum)
+			if !ok {
+				// Bulk mode ran out of input lines before the sheet ran out
+				// of cells; leave the rest of the page blank.
+				continue
+			}
 
 			// Calculate position
-			x := marginLeft + float64(col)*(labelWidth+labelGutterX)
-			y := marginTop + float64(row)*labelHeight
-
-			// Generate QR code
-			text := fmt.Sprintf("%s%0*d", cfg.prefix, cfg.leadingZeros, currentNum)
-			qrPath, err := generateQR(text, tf)
-			if err != nil {
-				return fmt.Errorf("error generating QR code for %s: %v", text, err)
+			x := sheet.MarginLeft + float64(col)*(sheet.LabelW+sheet.GutterX)
+			y := sheet.MarginTop + float64(row)*(sheet.LabelH+sheet.GutterY)
+
+			// Generate the label's code
+			if err := validateSymbologyContent(cfg.symbology, text); err != nil {
+				return fmt.Errorf("invalid content for %s: %v", text, err)
 			}
 
-			// Add QR code to PDF
-			pdf.Image(qrPath, x, y+qrCodeOffsetY, qrCodeSize, qrCodeSize, false, "", 0, "")
+			codeWidth := sheet.CodeSize
+			if isLinearSymbology(cfg.symbology) {
+				// Linear codes don't need the fixed square code box; give them
+				// whatever width is left on the label instead. 2D codes (QR,
+				// DataMatrix, PDF417) keep the square box so they stay
+				// aspect-correct and scanner-friendly.
+				codeWidth = sheet.LabelW - sheet.CodeSize - qrCodeMarginX
+			}
+
+			if err := drawCode(pdf, cfg.symbology, text, x, y+sheet.CodeOffsetY, codeWidth, sheet.CodeSize); err != nil {
+				return fmt.Errorf("error generating code for %s: %v", text, err)
+			}
 
 			// Add text
-			pdf.SetFont("Helvetica", "", 8)
-			pdf.Text(x+qrCodeSize+qrCodeMarginX, y+labelHeight/2, text)
+			pdf.SetFont(sheet.Font, "", sheet.FontSize)
+			pdf.Text(x+codeWidth+qrCodeMarginX, y+sheet.LabelH/2, text)
 
 			// Draw border if enabled
 			if cfg.showBorders {
-				pdf.Rect(x, y, labelWidth, labelHeight, "D")
+				pdf.Rect(x, y, sheet.LabelW, sheet.LabelH, "D")
 			}
+
+			pt.addDone(1)
 		}
 	}
 	return nil
 }
 
-func generateQR(text string, tf *tempFiles) (string, error) {
-	// Generate QR code
+// drawCode encodes text using the requested symbology and draws it into the
+// PDF at (x, y) with the given size. QR and PDF417 are registered as in-memory
+// images keyed by payload (registerBarcodeImage); the other symbologies are
+// registered with fpdf directly via the contrib/barcode helpers, which skips
+// the PNG step entirely.
+func drawCode(pdf *fpdf.Fpdf, symbology, text string, x, y, w, h float64) error {
+	switch symbology {
+	case symbologyQR:
+		name, err := generateQR(pdf, text)
+		if err != nil {
+			return err
+		}
+		pdf.Image(name, x, y, w, h, false, "", 0, "")
+		return nil
+
+	case symbologyDataMatrix:
+		name := fpdfbarcode.RegisterDataMatrix(pdf, text)
+		fpdfbarcode.Barcode(pdf, name, x, y, w, h, false)
+		return nil
+
+	case symbologyCode128:
+		name := fpdfbarcode.RegisterCode128(pdf, text)
+		fpdfbarcode.Barcode(pdf, name, x, y, w, h, false)
+		return nil
+
+	case symbologyEAN13:
+		name := fpdfbarcode.RegisterEAN(pdf, text)
+		fpdfbarcode.Barcode(pdf, name, x, y, w, h, false)
+		return nil
+
+	case symbologyI2of5:
+		name := fpdfbarcode.RegisterTwoOfFive(pdf, text, true)
+		fpdfbarcode.Barcode(pdf, name, x, y, w, h, false)
+		return nil
+
+	case symbologyPDF417:
+		code, err := pdf417.Encode(text, pdf417SecurityLevel)
+		if err != nil {
+			return fmt.Errorf("failed to encode pdf417: %v", err)
+		}
+		name, err := registerBarcodeImage(pdf, "pdf417:"+text, code)
+		if err != nil {
+			return err
+		}
+		pdf.Image(name, x, y, w, h, false, "", 0, "")
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported symbology %q", symbology)
+	}
+}
+
+// generateQR encodes text as a QR code and registers it as an in-memory
+// image, returning the registered name to pass to pdf.Image.
+func generateQR(pdf *fpdf.Fpdf, text string) (string, error) {
 	qrCode, err := qr.Encode(text, qr.M, qr.Auto)
 	if err != nil {
 		return "", fmt.Errorf("failed to encode QR code: %v", err)
@@ -291,34 +1206,35 @@ func generateQR(text string, tf *tempFiles) (string, error) {
 		return "", fmt.Errorf("failed to scale QR code: %v", err)
 	}
 
-	// Convert to RGBA
-	bounds := qrCode.Bounds()
+	return registerBarcodeImage(pdf, "qr:"+text, qrCode)
+}
+
+// registerBarcodeImage rasterizes a barcode.Barcode to PNG in memory and
+// registers it with fpdf under name, so pdf.Image can draw it without ever
+// touching disk. Identical names (i.e. identical payloads) are only
+// registered once.
+func registerBarcodeImage(pdf *fpdf.Fpdf, name string, code barcode.Barcode) (string, error) {
+	if info := pdf.GetImageInfo(name); info != nil {
+		return name, nil
+	}
+
+	bounds := code.Bounds()
 	rgbaImg := image.NewRGBA(bounds)
 	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
 		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			rgbaImg.Set(x, y, qrCode.At(x, y))
+			rgbaImg.Set(x, y, code.At(x, y))
 		}
 	}
 
-	// Create temporary file for QR code
-	tmpFile, err := os.CreateTemp("", "asn-label-*.png")
-	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %v", err)
-	}
-
-	// Add the file to our tracking list
-	tf.add(tmpFile.Name())
-
-	// Save QR code to temp file
-	if err := png.Encode(tmpFile, rgbaImg); err != nil {
-		tmpFile.Close()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, rgbaImg); err != nil {
 		return "", fmt.Errorf("failed to encode PNG: %v", err)
 	}
 
-	// Close the file
-	if err := tmpFile.Close(); err != nil {
-		return "", fmt.Errorf("failed to close temp file: %v", err)
+	pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "PNG"}, &buf)
+	if err := pdf.Error(); err != nil {
+		return "", fmt.Errorf("failed to register barcode image: %v", err)
 	}
 
-	return tmpFile.Name(), nil
+	return name, nil
 }